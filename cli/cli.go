@@ -3,15 +3,22 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"runtime"
 )
 
 // Options contains CLI arguments passed to the program.
 type Options struct {
-	Help     bool
-	Debug    bool
-	Quiet    bool
-	BasePath string
-	Filter   string
+	Help       bool
+	Debug      bool
+	Quiet      bool
+	BasePath   string
+	Filter     string
+	NoTest     bool
+	NoTestData bool
+	Jobs       int
+	Manifest   bool
+	Verify     bool
+	Link       string
 }
 
 // ParseOptions parses the command line options and returns a struct filled with
@@ -24,6 +31,12 @@ func ParseOptions() Options {
 	flag.BoolVar(&opt.Quiet, "quiet", false, "Suppress normal output.")
 	flag.StringVar(&opt.BasePath, "output", "vendor", "Directory to write vendored packages.")
 	flag.StringVar(&opt.Filter, "filter", "", "Filter which files are written to directory.")
+	flag.BoolVar(&opt.NoTest, "notest", false, "Skip files ending in _test.go.")
+	flag.BoolVar(&opt.NoTestData, "notestdata", false, "Skip directories named testdata.")
+	flag.IntVar(&opt.Jobs, "jobs", runtime.NumCPU(), "Number of modules to copy concurrently.")
+	flag.BoolVar(&opt.Manifest, "manifest", false, "Write a vend.json manifest describing the vendored set.")
+	flag.BoolVar(&opt.Verify, "verify", false, "Verify copied modules against go.sum.")
+	flag.StringVar(&opt.Link, "link", "copy", "How to place files in the vendor directory: hard, reflink, or copy.")
 	flag.Parse()
 
 	return opt
@@ -0,0 +1,68 @@
+package file
+
+import (
+	"sort"
+	"strings"
+)
+
+// groupNestedDeps partitions deps into groups that are safe to copy in
+// parallel: any two deps whose vendor destinations nest one inside the
+// other (e.g. the "module" and "module/v2" major-version convention) land
+// in the same group, sorted shortest-path-first so the outer module is
+// copied before the inner one. CopyDependencies must process each returned
+// group on a single worker, sequentially, since copyDirectory's
+// prune-on-empty removal of one dep's destination would otherwise race
+// against another worker still writing into a path nested underneath it.
+// Independent deps each get their own single-element group and copy
+// concurrently as before.
+func groupNestedDeps(deps []Dep) [][]Dep {
+	parent := make([]int, len(deps))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) {
+		ra, rb := find(a), find(b)
+		if ra != rb {
+			parent[ra] = rb
+		}
+	}
+
+	for i := range deps {
+		for j := i + 1; j < len(deps); j++ {
+			if pathsNest(deps[i].Path, deps[j].Path) {
+				union(i, j)
+			}
+		}
+	}
+
+	byRoot := make(map[int][]Dep)
+	var rootOrder []int
+	for i, d := range deps {
+		r := find(i)
+		if _, ok := byRoot[r]; !ok {
+			rootOrder = append(rootOrder, r)
+		}
+		byRoot[r] = append(byRoot[r], d)
+	}
+
+	groups := make([][]Dep, 0, len(rootOrder))
+	for _, r := range rootOrder {
+		group := byRoot[r]
+		sort.Slice(group, func(a, b int) bool { return len(group[a].Path) < len(group[b].Path) })
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// pathsNest reports whether a and b are the same vendor destination, or one
+// is a subdirectory of the other.
+func pathsNest(a, b string) bool {
+	return a == b || strings.HasPrefix(a+"/", b+"/") || strings.HasPrefix(b+"/", a+"/")
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/GannettDigital/vend/cli"
@@ -23,6 +24,12 @@ type VendorDir struct {
 	mod            GoMod
 	deps           []Dep
 	filter         *regexp.Regexp
+	noTest         bool
+	noTestData     bool
+	jobs           int
+	manifest       bool
+	verify         bool
+	link           string
 	debug          bool
 	quiet          bool
 }
@@ -46,13 +53,30 @@ func InitVendorDir(options cli.Options) VendorDir {
 		output.Fatal("Output path (%q) must be a subdirectory of the current directory (%q).", basePath, wd)
 	}
 
+	jobs := options.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	switch options.Link {
+	case "hard", "reflink", "copy":
+	default:
+		output.Fatal("Invalid -link mode %q: must be hard, reflink, or copy.", options.Link)
+	}
+
 	return VendorDir{
-		basePath: basePath,
-		mod:      ParseModJSON(cli.ReadModJSON()),
-		deps:     ParseDownloadJSON(cli.ReadDownloadJSON()),
-		filter:   filterRegexp,
-		debug:    options.Debug,
-		quiet:    options.Quiet,
+		basePath:   basePath,
+		mod:        ParseModJSON(cli.ReadModJSON()),
+		deps:       ParseDownloadJSON(cli.ReadDownloadJSON()),
+		filter:     filterRegexp,
+		noTest:     options.NoTest,
+		noTestData: options.NoTestData,
+		jobs:       jobs,
+		manifest:   options.Manifest,
+		verify:     options.Verify,
+		link:       options.Link,
+		debug:      options.Debug,
+		quiet:      options.Quiet,
 	}
 }
 
@@ -83,47 +107,132 @@ func (v *VendorDir) CopyDependencies() {
 
 	v.clear()
 
-	for _, d := range v.deps {
-		if !v.quiet {
-			fmt.Printf("vend: copying %s (%s)\n", d.Path, d.Version)
+	printer := make(chan string)
+	printerDone := make(chan struct{})
+	go func() {
+		defer close(printerDone)
+		for msg := range printer {
+			fmt.Println(msg)
 		}
-		copied := v.copy(d.Dir, v.vendPath(d.Path))
-		if !copied && v.filter != nil {
-			// This ignores errors because some parts of the path (especially the
-			// hostname) might be shared between multiple, un-related packages.
-			for p := d.Path; p != "."; p = filepath.Dir(p) {
-				if v.debug {
-					fmt.Fprintf(os.Stderr, "pruning: %s\n", p)
-				}
-				err := v.remove(v.vendPath(p))
-				if err != nil {
-					if os.IsNotExist(err) {
-						continue
-					} else if errors.Is(err, syscall.ENOTEMPTY) {
-						break
+	}()
+
+	var sums map[string]string
+	if v.verify {
+		sums = readGoSumHashes("go.sum")
+	}
+
+	// Deps whose vendor destinations nest one inside the other (e.g. the
+	// "module"/"module/v2" major-version convention) must not copy on
+	// separate workers: copyDirectory's prune-on-empty removal of one
+	// dep's destination could race against another worker still writing
+	// into a path nested underneath it. groupNestedDeps keeps those on a
+	// single job so they copy sequentially; independent deps still run
+	// in parallel.
+	jobs := make(chan []Dep)
+	var resultMu sync.Mutex
+	var prunePaths []string
+	copiedByPath := make(map[string]bool, len(v.deps))
+
+	var wg sync.WaitGroup
+	for i := 0; i < v.jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range jobs {
+				for _, d := range group {
+					if !v.quiet {
+						printer <- fmt.Sprintf("vend: copying %s (%s)", d.Path, d.Version)
+					}
+					copied := v.copy(d.Dir, v.vendPath(d.Path))
+					if v.verify {
+						// Verify against the source directory, not the vendored
+						// destination, so -filter/-notest pruning can't cause a
+						// spurious mismatch.
+						v.verifyDep(d, sums)
 					}
-					output.OnError(err, "Error removing path")
+					resultMu.Lock()
+					copiedByPath[d.Path] = copied
+					if !copied && v.filter != nil {
+						prunePaths = append(prunePaths, d.Path)
+					}
+					resultMu.Unlock()
 				}
 			}
-		}
+		}()
 	}
 
-	for _, r := range v.mod.Replace {
-		if r.Old.Path != r.New.Path {
-			if !v.quiet {
-				fmt.Printf("vend: replacing %s with %s\n", r.Old.Path, r.New.Path)
+	for _, group := range groupNestedDeps(v.deps) {
+		jobs <- group
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Pruning walks up shared ancestor directories, so it must only start
+	// once every worker above has finished copying: concurrent removes of
+	// the same ancestor race on ENOTEMPTY.
+	for _, p := range prunePaths {
+		// This ignores errors because some parts of the path (especially the
+		// hostname) might be shared between multiple, un-related packages.
+		for ; p != "."; p = filepath.Dir(p) {
+			if v.debug {
+				fmt.Fprintf(os.Stderr, "pruning: %s\n", p)
+			}
+			err := v.remove(v.vendPath(p))
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				} else if errors.Is(err, syscall.ENOTEMPTY) {
+					break
+				}
+				output.OnError(err, "Error removing path")
 			}
-			newPath := v.vendPath(r.New.Path)
-			oldPath := v.vendPath(r.Old.Path)
-			// If the directory is in the vendor folder it was copied from the
-			// module cache so we can just rename it. Otherwise it's a local
-			// directory located somewhere else that needs copying in.
-			if v.exists(newPath) {
-				v.copy(newPath, oldPath)
-				v.removeAll(newPath)
-			} else {
-				v.copy(r.New.Path, oldPath)
+		}
+	}
+
+	replaces := make(chan Replace)
+	var replaceWg sync.WaitGroup
+	for i := 0; i < v.jobs; i++ {
+		replaceWg.Add(1)
+		go func() {
+			defer replaceWg.Done()
+			for r := range replaces {
+				v.applyReplace(r, printer)
 			}
+		}()
+	}
+
+	for _, r := range v.mod.Replace {
+		replaces <- r
+	}
+	close(replaces)
+	replaceWg.Wait()
+
+	close(printer)
+	<-printerDone
+
+	if v.manifest {
+		v.writeManifest(copiedByPath)
+	}
+}
+
+// applyReplace renames or copies a single replace directive's new path over
+// its old path within the vendor directory, logging through printer so
+// output from the deps and replace worker pools stays serialized.
+func (v *VendorDir) applyReplace(r Replace, printer chan<- string) {
+	if r.Old.Path != r.New.Path {
+		if !v.quiet {
+			printer <- fmt.Sprintf("vend: replacing %s with %s", r.Old.Path, r.New.Path)
+		}
+		newPath := v.vendPath(r.New.Path)
+		oldPath := v.vendPath(r.Old.Path)
+		// If the directory is in the vendor folder it was copied from the
+		// module cache so we can just rename it. Otherwise it's a local
+		// directory located somewhere else that needs copying in.
+		if v.exists(newPath) {
+			v.copy(newPath, oldPath)
+			v.removeAll(newPath)
+		} else {
+			v.copy(r.New.Path, oldPath)
 		}
 	}
 }
@@ -198,14 +307,33 @@ func (v *VendorDir) copy(src string, dest string) bool {
 	case info.Mode()&os.ModeSymlink != 0:
 		return false // Completely ignore symlinks.
 	case info.IsDir():
+		if v.noTestData && info.Name() == "testdata" {
+			return false
+		}
 		return v.copyDirectory(src, dest)
-	case v.filter == nil || v.filter.MatchString(dest):
-		return v.copyFile(src, dest)
-	default:
+	case v.noTest && strings.HasSuffix(src, "_test.go"):
 		return false
+	default:
+		return v.copyFile(src, dest)
 	}
 }
 
+// legalFilePrefixes lists the basename prefixes, matched case-insensitively,
+// that are always copied regardless of -filter. This mirrors the files
+// "go mod vendor" preserves so legal notices aren't accidentally dropped.
+var legalFilePrefixes = []string{"license", "copying", "notice", "patents", "authors", "legal"}
+
+// isLegalFile reports whether name matches one of legalFilePrefixes.
+func isLegalFile(name string) bool {
+	lower := strings.ToLower(name)
+	for _, prefix := range legalFilePrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // copyDirectory will copy directories.
 func (v *VendorDir) copyDirectory(src string, dest string) bool {
 	err := os.MkdirAll(dest, 0755)
@@ -230,11 +358,48 @@ func (v *VendorDir) copyDirectory(src string, dest string) bool {
 	return copied
 }
 
-// copyFile will copy files.
+// tryLink places dest via link (os.Link for -link=hard, reflink for
+// -link=reflink), falling back to a plain copy on failure (e.g. EXDEV across
+// filesystems, or an unsupported reflink). Since a hardlink or reflink can
+// share storage with a read-only module cache entry, the source's read-only
+// bit is preserved on dest so an accidental edit fails loudly instead of
+// silently mutating the cache.
+func (v *VendorDir) tryLink(src, dest string, link func(string, string) error) bool {
+	if err := link(src, dest); err != nil {
+		return false
+	}
+
+	info, err := os.Stat(src)
+	output.OnError(err, "Error stat'ing source file")
+	if info.Mode()&0222 == 0 {
+		err := os.Chmod(dest, info.Mode().Perm())
+		output.OnError(err, "Error preserving read-only permissions")
+	}
+
+	return true
+}
+
+// copyFile will copy files. Files matching isLegalFile are always copied;
+// otherwise the copy is skipped unless it passes v.filter.
 func (v *VendorDir) copyFile(src string, dest string) bool {
+	if !isLegalFile(filepath.Base(dest)) && v.filter != nil && !v.filter.MatchString(dest) {
+		return false
+	}
+
 	err := os.MkdirAll(filepath.Dir(dest), 0755)
 	output.OnError(err, "Error creating directories")
 
+	switch v.link {
+	case "hard":
+		if v.tryLink(src, dest, os.Link) {
+			return true
+		}
+	case "reflink":
+		if v.tryLink(src, dest, reflink) {
+			return true
+		}
+	}
+
 	d, err := os.Create(dest)
 	output.OnError(err, "Error creating file")
 	defer d.Close()
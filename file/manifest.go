@@ -0,0 +1,106 @@
+package file
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/GannettDigital/vend/output"
+)
+
+// ManifestEntry describes a single vendored dependency within vend.json.
+type ManifestEntry struct {
+	Path      string `json:"path"`
+	Version   string `json:"version"`
+	GoModHash string `json:"goModHash,omitempty"`
+	Dir       string `json:"dir"`
+	Replaced  bool   `json:"replaced,omitempty"`
+}
+
+// Manifest is the top level structure written to vend.json, a machine
+// readable inventory of what CopyDependencies actually vendored.
+type Manifest struct {
+	Module       string          `json:"module"`
+	GoVersion    string          `json:"goVersion"`
+	Filter       string          `json:"filter,omitempty"`
+	Dependencies []ManifestEntry `json:"dependencies"`
+}
+
+// writeManifest writes vend.json at the vendor root, describing the set of
+// dependencies CopyDependencies actually vendored. copied reports, per
+// dep.Path, whether anything from that dependency survived -filter/-notest/
+// -notestdata pruning; deps that didn't are omitted entirely so vend.json
+// never points at a directory that doesn't exist under the vendor root.
+func (v *VendorDir) writeManifest(copied map[string]bool) {
+	hashes := readGoSumHashes("go.sum")
+
+	replaced := make(map[string]bool, len(v.mod.Replace))
+	for _, r := range v.mod.Replace {
+		replaced[r.Old.Path] = true
+	}
+
+	entries := make([]ManifestEntry, 0, len(v.deps))
+	for _, d := range v.deps {
+		if !copied[d.Path] {
+			continue
+		}
+		entries = append(entries, ManifestEntry{
+			Path:      d.Path,
+			Version:   d.Version,
+			GoModHash: hashes[d.Path+" "+d.Version+"/go.mod"],
+			Dir:       d.Dir,
+			Replaced:  replaced[d.Path],
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	var filterStr string
+	if v.filter != nil {
+		filterStr = v.filter.String()
+	}
+
+	manifest := Manifest{
+		Module:       v.mod.Module.Path,
+		GoVersion:    runtime.Version(),
+		Filter:       filterStr,
+		Dependencies: entries,
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	output.OnError(err, "Error marshaling vend.json manifest")
+
+	err = ioutil.WriteFile(v.vendPath("vend.json"), data, 0644)
+	output.OnError(err, "Error writing vend.json manifest")
+}
+
+// readGoSumHashes reads a go.sum file into a map keyed by "module version",
+// e.g. "golang.org/x/mod v0.4.0/go.mod", to its h1: hash. Missing files
+// return an empty map since go.sum is optional for modules without it.
+func readGoSumHashes(path string) map[string]string {
+	hashes := make(map[string]string)
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return hashes
+		}
+		output.OnError(err, "Error opening go.sum")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		hashes[fields[0]+" "+fields[1]] = fields[2]
+	}
+	output.OnError(scanner.Err(), "Error reading go.sum")
+
+	return hashes
+}
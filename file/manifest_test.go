@@ -0,0 +1,56 @@
+package file
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteManifestOmitsPrunedDeps(t *testing.T) {
+	dir := t.TempDir()
+
+	origWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(origWd)
+
+	v := VendorDir{
+		basePath: dir,
+		deps: []Dep{
+			{Path: "example.com/kept", Version: "v1.0.0", Dir: "/tmp/kept"},
+			{Path: "example.com/pruned", Version: "v1.0.0", Dir: "/tmp/pruned"},
+		},
+	}
+
+	// "pruned" stands in for a dep that -filter/-notest/-notestdata left
+	// with nothing copied into the vendor tree; it must not appear in
+	// vend.json even though it's still in v.deps.
+	copied := map[string]bool{
+		"example.com/kept":   true,
+		"example.com/pruned": false,
+	}
+
+	v.writeManifest(copied)
+
+	data, err := os.ReadFile(filepath.Join(dir, "vend.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(manifest.Dependencies) != 1 {
+		t.Fatalf("expected 1 dependency in manifest, got %d: %+v", len(manifest.Dependencies), manifest.Dependencies)
+	}
+	if manifest.Dependencies[0].Path != "example.com/kept" {
+		t.Errorf("expected %q in manifest, got %q", "example.com/kept", manifest.Dependencies[0].Path)
+	}
+}
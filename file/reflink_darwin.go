@@ -0,0 +1,44 @@
+package file
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// sysCloneFileAt is the clonefileat(2) syscall number on Darwin. Called with
+// AT_FDCWD for both directory file descriptors it behaves like clonefile(2),
+// cloning src's data via copy-on-write without duplicating disk blocks.
+const sysCloneFileAt = 462
+
+// atFDCWD tells clonefileat to resolve both paths relative to the current
+// working directory, matching plain clonefile(2) semantics.
+const atFDCWD = -2
+
+// reflink attempts to create a copy-on-write clone of src at dest using
+// clonefile(2). On failure it returns an error, leaving the caller to fall
+// back to a plain copy.
+func reflink(src, dest string) error {
+	srcPtr, err := syscall.BytePtrFromString(src)
+	if err != nil {
+		return err
+	}
+	destPtr, err := syscall.BytePtrFromString(dest)
+	if err != nil {
+		return err
+	}
+
+	_, _, errno := syscall.Syscall6(
+		sysCloneFileAt,
+		uintptr(atFDCWD),
+		uintptr(unsafe.Pointer(srcPtr)),
+		uintptr(atFDCWD),
+		uintptr(unsafe.Pointer(destPtr)),
+		0,
+		0,
+	)
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// ficlone is the FICLONE ioctl request number on Linux, used to create a
+// copy-on-write clone of a file's data without duplicating disk blocks.
+const ficlone = 0x40049409
+
+// reflink attempts to create a copy-on-write clone of src at dest using the
+// FICLONE ioctl. On failure it removes any partially created dest and
+// returns an error, leaving the caller to fall back to a plain copy.
+func reflink(src, dest string) error {
+	s, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	d, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, d.Fd(), ficlone, s.Fd())
+	if errno != 0 {
+		os.Remove(dest)
+		return errno
+	}
+
+	return nil
+}
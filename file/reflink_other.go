@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package file
+
+import "errors"
+
+// reflink is only implemented on Linux and Darwin; elsewhere callers fall
+// back to a plain copy.
+func reflink(src, dest string) error {
+	return errors.New("reflink is not supported on this platform")
+}
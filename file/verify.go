@@ -0,0 +1,111 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/GannettDigital/vend/output"
+)
+
+// verifyDep checks a dependency's source directory against the hashes
+// recorded in go.sum, using the same dirhash algorithm "go mod verify"
+// relies on. Missing go.sum entries are not an error, since not every
+// dependency with a go.mod is necessarily present there.
+func (v *VendorDir) verifyDep(d Dep, sums map[string]string) {
+	prefix := d.Path + "@" + d.Version
+
+	if want, ok := sums[d.Path+" "+d.Version]; ok {
+		got, err := hashDir(d.Dir, prefix)
+		output.OnError(err, "Error hashing module directory for verification")
+		if got != want {
+			output.Fatal("vend: checksum mismatch for %s: go.sum has %s, computed %s", prefix, want, got)
+		}
+	}
+
+	if want, ok := sums[d.Path+" "+d.Version+"/go.mod"]; ok {
+		got, err := hashGoMod(filepath.Join(d.Dir, "go.mod"), prefix)
+		output.OnError(err, "Error hashing go.mod for verification")
+		if got != want {
+			output.Fatal("vend: go.mod checksum mismatch for %s: go.sum has %s, computed %s", prefix, want, got)
+		}
+	}
+}
+
+// hashDir computes the h1: dirhash of dir the same way
+// golang.org/x/mod/sumdb/dirhash.HashDir does: sha256 over a sorted list of
+// "sha256(file)  prefix/path\n" lines, base64-encoded.
+func hashDir(dir, prefix string) (string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	var lines []string
+	for _, f := range files {
+		sum, err := hashFile(filepath.Join(dir, f))
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, fmt.Sprintf("%x  %s/%s\n", sum, prefix, f))
+	}
+
+	return hashLines(lines), nil
+}
+
+// hashGoMod computes the h1: hash of a standalone go.mod file the same way
+// dirhash.Hash1 does for the "<module> <version>/go.mod" go.sum entry.
+func hashGoMod(path, prefix string) (string, error) {
+	sum, err := hashFile(path)
+	if err != nil {
+		return "", err
+	}
+	return hashLines([]string{fmt.Sprintf("%x  %s/go.mod\n", sum, prefix)}), nil
+}
+
+// hashLines hashes a sorted list of "sha256  path\n" lines into a single h1:
+// digest, as dirhash.Hash1 does.
+func hashLines(lines []string) string {
+	h := sha256.New()
+	for _, line := range lines {
+		io.WriteString(h, line)
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// hashFile returns the sha256 digest of a single file's contents.
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
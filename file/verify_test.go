@@ -0,0 +1,58 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// These expected h1: values were computed independently of this package,
+// using sha256sum/base64 directly against the fixture files below, so a
+// regression in hashDir/hashGoMod/hashLines (e.g. wrong prefix, missing
+// newline, or sorting by the wrong key) has something external to fail
+// against instead of only re-deriving the same bug.
+func TestHashDirAndHashGoMod(t *testing.T) {
+	dir := t.TempDir()
+
+	goModContent := "module example.com/foo\n\ngo 1.16\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goModContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pkg", "foo.go"), []byte("package foo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const prefix = "example.com/foo@v0.1.0"
+
+	tests := []struct {
+		name string
+		got  func() (string, error)
+		want string
+	}{
+		{
+			name: "go.mod",
+			got:  func() (string, error) { return hashGoMod(filepath.Join(dir, "go.mod"), prefix) },
+			want: "h1:rzrBAEb8Vl/+ShT8HXUMQRGUbVaiYJdo2q1In4sR1Dw=",
+		},
+		{
+			name: "dir",
+			got:  func() (string, error) { return hashDir(dir, prefix) },
+			want: "h1:6Sgzch6qEuF55zWv/NWg3veyDN2UnHAje8HqntI/RyI=",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.got()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}